@@ -2,7 +2,11 @@ package libts
 
 import (
 	"context"
+	"net/netip"
 	"runtime"
+	"slices"
+	"strconv"
+	"strings"
 
 	"tailscale.com/client/tailscale"
 	"tailscale.com/ipn"
@@ -10,6 +14,58 @@ import (
 	"tailscale.com/tailcfg"
 )
 
+// The daemon version that added Status.HaveNodeKey. Daemons older than this don't
+// populate the field, so we can't trust it to distinguish "no key" from "not reported".
+const minHaveNodeKeyVersion = "1.48.0"
+
+// Returns true if version is at least minHaveNodeKeyVersion, ignoring any "-dirty"
+// or commit-hash suffix.
+func supportsHaveNodeKey(version string) bool {
+	if i := strings.IndexByte(version, '-'); i != -1 {
+		version = version[:i]
+	}
+	return compareDottedVersions(version, minHaveNodeKeyVersion) >= 0
+}
+
+// Compare two dotted version strings (e.g. "1.48.0") component by component.
+// Returns a negative number, zero, or a positive number as a is less than, equal
+// to, or greater than b. Missing or non-numeric components are treated as zero.
+func compareDottedVersions(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var av, bv int
+		if i < len(as) {
+			av, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			bv, _ = strconv.Atoi(bs[i])
+		}
+		if av != bv {
+			return av - bv
+		}
+	}
+
+	return 0
+}
+
+// The routes that together advertise this node as an exit node.
+var exitNodeRoutes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/0"),
+	netip.MustParsePrefix("::/0"),
+}
+
+// Returns true if routes includes both routes that make up an exit node advertisement.
+func isAdvertisingExitNode(routes []netip.Prefix) bool {
+	for _, r := range exitNodeRoutes {
+		if !slices.Contains(routes, r) {
+			return false
+		}
+	}
+	return true
+}
+
 var ts tailscale.LocalClient
 
 // Return the Tailscale daemon status. Returns an error if the daemon is not running.
@@ -25,16 +81,31 @@ func StartLoginInteractiveWillOpenBrowser() bool {
 
 // Start an interactive login flow. On macOS, this will automatically open the user's web browser.
 func StartLoginInteractive(ctx context.Context) error {
-	// Workaround for a Tailscale bug where Tailscale will go into the Starting... state
-	// without populating the AuthURL when reauthenticating. For some reason, calling
-	// Start first with no options makes the AuthURL populate.
-	//
-	// We need AuthURL so we can display UI elements related to the login process.
-	err := ts.Start(ctx, ipn.Options{})
+	status, err := ts.Status(ctx)
 	if err != nil {
 		return err
 	}
 
+	if !supportsHaveNodeKey(status.Version) {
+		// Workaround for a Tailscale bug where Tailscale will go into the Starting... state
+		// without populating the AuthURL when reauthenticating. For some reason, calling
+		// Start first with no options makes the AuthURL populate.
+		//
+		// We need AuthURL so we can display UI elements related to the login process.
+		if err := ts.Start(ctx, ipn.Options{}); err != nil {
+			return err
+		}
+
+		return ts.StartLoginInteractive(ctx)
+	}
+
+	// Status.HaveNodeKey tells us whether this profile has ever registered with the
+	// coordination server. If it hasn't, Start on its own triggers the RegisterRequest
+	// that populates AuthURL; StartLoginInteractive is only needed to force a reauth.
+	if !status.HaveNodeKey {
+		return ts.Start(ctx, ipn.Options{})
+	}
+
 	return ts.StartLoginInteractive(ctx)
 }
 
@@ -119,3 +190,61 @@ func SetExitNode(ctx context.Context, peer *ipnstate.PeerStatus) error {
 
 	return nil
 }
+
+// Set the routes this node advertises as a subnet router.
+func SetAdvertisedRoutes(ctx context.Context, routes []netip.Prefix) error {
+	return EditPrefs(ctx, &ipn.MaskedPrefs{
+		Prefs: ipn.Prefs{
+			AdvertiseRoutes: routes,
+		},
+		AdvertiseRoutesSet: true,
+	})
+}
+
+// Advertise (or stop advertising) this node as an exit node, leaving any other
+// advertised subnet routes untouched.
+func SetAdvertiseExitNode(ctx context.Context, advertise bool) error {
+	prefs, err := Prefs(ctx)
+	if err != nil {
+		return err
+	}
+
+	routes := slices.DeleteFunc(slices.Clone(prefs.AdvertiseRoutes), func(r netip.Prefix) bool {
+		return slices.Contains(exitNodeRoutes, r)
+	})
+	if advertise {
+		routes = append(routes, exitNodeRoutes...)
+	}
+
+	return SetAdvertisedRoutes(ctx, routes)
+}
+
+// Set whether this node accepts subnet routes advertised by other nodes on the tailnet.
+func SetAcceptRoutes(ctx context.Context, accept bool) error {
+	return EditPrefs(ctx, &ipn.MaskedPrefs{
+		Prefs: ipn.Prefs{
+			RouteAll: accept,
+		},
+		RouteAllSet: true,
+	})
+}
+
+// Set whether devices on the LAN can be reached while this node is in use as an exit node.
+func SetExitNodeAllowLANAccess(ctx context.Context, allow bool) error {
+	return EditPrefs(ctx, &ipn.MaskedPrefs{
+		Prefs: ipn.Prefs{
+			ExitNodeAllowLANAccess: allow,
+		},
+		ExitNodeAllowLANAccessSet: true,
+	})
+}
+
+// Set the tags this node advertises itself as, for use in ACL policies.
+func SetAdvertisedTags(ctx context.Context, tags []string) error {
+	return EditPrefs(ctx, &ipn.MaskedPrefs{
+		Prefs: ipn.Prefs{
+			AdvertiseTags: tags,
+		},
+		AdvertiseTagsSet: true,
+	})
+}
@@ -0,0 +1,53 @@
+package libts
+
+import (
+	"context"
+
+	"tailscale.com/ipn/ipnstate"
+	"tailscale.com/tka"
+	"tailscale.com/types/key"
+)
+
+// Co-sign a node's key with the tailnet lock key, allowing it onto the tailnet.
+func SignNode(ctx context.Context, nodeKey key.NodePublic, rotationKey key.NLPublic) error {
+	rotationPub, err := rotationKey.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	return ts.NetworkLockSign(ctx, nodeKey, rotationPub)
+}
+
+// List nodes that have been signed into the tailnet lock's trust chain.
+//
+// Nodes tailnet lock hasn't signed never make it into the netmap in the first place, so
+// the current peer list already is the signed set; LockStatus's FilteredPeers is the
+// complementary view of who got held back.
+func ListSignedNodes(ctx context.Context) ([]*ipnstate.PeerStatus, error) {
+	status, err := Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*ipnstate.PeerStatus, 0, len(status.Peer))
+	for _, peer := range status.Peer {
+		nodes = append(nodes, peer)
+	}
+
+	return nodes, nil
+}
+
+// Add or remove tailnet lock keys.
+func ModifyLockKeys(ctx context.Context, add, remove []tka.Key) error {
+	return ts.NetworkLockModify(ctx, add, remove)
+}
+
+// Disable tailnet lock using the disablement secret generated when it was enabled.
+func DisableLock(ctx context.Context, secret []byte) error {
+	return ts.NetworkLockDisable(ctx, secret)
+}
+
+// Generate a new tailnet lock key, for use as one of the lock's trusted signing keys.
+func GenerateLockKey(ctx context.Context) (key.NLPrivate, error) {
+	return key.NewNLPrivate(), nil
+}
@@ -0,0 +1,32 @@
+package libts
+
+import (
+	"context"
+
+	"tailscale.com/ipn"
+)
+
+// List all stored login profiles on this machine (e.g. separate personal/work tailnets),
+// along with the ID of the currently active one.
+func Profiles(ctx context.Context) ([]ipn.LoginProfile, ipn.ProfileID, error) {
+	return ts.ProfileStatus(ctx)
+}
+
+// Switch the daemon to an already-added login profile.
+func SwitchProfile(ctx context.Context, id ipn.ProfileID) error {
+	return ts.SwitchProfile(ctx, id)
+}
+
+// Add a new, empty login profile and start an interactive login flow for it.
+func AddProfile(ctx context.Context) error {
+	if err := ts.AddProfile(ctx); err != nil {
+		return err
+	}
+
+	return StartLoginInteractive(ctx)
+}
+
+// Delete a stored login profile.
+func DeleteProfile(ctx context.Context, id ipn.ProfileID) error {
+	return ts.DeleteProfile(ctx, id)
+}
@@ -3,12 +3,15 @@ package libts
 import (
 	"context"
 	"fmt"
+	"net/netip"
 	"slices"
 	"strings"
 
+	"tailscale.com/client/tailscale/apitype"
 	"tailscale.com/ipn"
 	"tailscale.com/ipn/ipnstate"
 	"tailscale.com/tailcfg"
+	"tailscale.com/tka"
 	"tailscale.com/types/key"
 )
 
@@ -25,6 +28,10 @@ type State struct {
 
 	// Auth URL. Empty if the user doesn't need to be authenticated.
 	AuthURL string
+	// True if the current profile has ever registered a node key with the coordination
+	// server. False distinguishes "never logged in" from "logged out but key cached",
+	// which a logged-out BackendState alone can't tell you.
+	HaveNodeKey bool
 	// User profile of the currently logged in user or nil if unknown.
 	User *tailcfg.UserProfile
 
@@ -56,6 +63,36 @@ type State struct {
 	RxBytes int64
 	// Total bytes sent to peers.
 	TxBytes int64
+
+	// Most recent error message reported by the backend, if any.
+	ErrMessage string
+
+	// Stored login profiles for this machine (e.g. separate personal/work tailnets).
+	Profiles []ipn.LoginProfile
+	// ID of the currently active login profile.
+	CurrentProfileID ipn.ProfileID
+
+	// True if this node is configured to accept incoming Taildrop transfers.
+	CanReceiveFiles bool
+	// Files waiting to be received over Taildrop.
+	IncomingFiles []apitype.WaitingFile
+
+	// Tailnet lock keys trusted to co-sign nodes. Empty if lock isn't enabled.
+	LockTrustedKeys []tka.Key
+	// Peers filtered out of the netmap because they're not yet signed by tailnet lock.
+	LockFilteredPeers []ipnstate.TKAFilteredPeer
+
+	// Subnet routes this node advertises as a subnet router.
+	AdvertisedRoutes []netip.Prefix
+	// True if this node advertises itself as an exit node.
+	AdvertisingExitNode bool
+	// True if this node accepts subnet routes advertised by other peers.
+	AcceptRoutes bool
+
+	// Latest available Tailscale client version, or nil if unknown.
+	ClientVersion *tailcfg.ClientVersion
+	// True if ClientVersion is newer than the running TSVersion.
+	UpdateAvailable bool
 }
 
 // Sort a list of node statuses by PeerName.
@@ -91,7 +128,17 @@ func NewIPNStateFromString(v string) (ipn.State, error) {
 }
 
 // Make a current State by making necessary Tailscale API calls.
+//
+// Prefer Watch for anything that needs to stay up to date, since repeated calls to
+// GetState each pay for a full Status/Prefs/LockStatus round trip.
 func GetState(ctx context.Context) (State, error) {
+	return fetchState(ctx)
+}
+
+// Make a current State by making the necessary Tailscale API calls directly, without
+// going through the IPN bus. Used by Watch to build its initial snapshot and to rebuild
+// the cached state whenever a notification signals a structural change.
+func fetchState(ctx context.Context) (State, error) {
 	status, err := Status(ctx)
 	if err != nil {
 		return State{}, err
@@ -107,18 +154,38 @@ func GetState(ctx context.Context) (State, error) {
 		return State{}, err
 	}
 
+	profiles, currentProfileID, err := Profiles(ctx)
+	if err != nil {
+		return State{}, err
+	}
+
+	incomingFiles, err := WaitingFiles(ctx)
+	if err != nil {
+		return State{}, err
+	}
+
 	backendState, err := NewIPNStateFromString(status.BackendState)
 	if err != nil {
 		return State{}, fmt.Errorf("cannot get status from state: %w", err)
 	}
 
 	state := State{
-		Prefs:        prefs,
-		AuthURL:      status.AuthURL,
-		BackendState: backendState,
-		TSVersion:    status.Version,
-		Self:         status.Self,
-		OwnedNodes:   make(map[string][]*ipnstate.PeerStatus),
+		Prefs:               prefs,
+		AuthURL:             status.AuthURL,
+		HaveNodeKey:         status.HaveNodeKey,
+		BackendState:        backendState,
+		TSVersion:           status.Version,
+		Self:                status.Self,
+		OwnedNodes:          make(map[string][]*ipnstate.PeerStatus),
+		Profiles:            profiles,
+		CurrentProfileID:    currentProfileID,
+		CanReceiveFiles:     !prefs.ShieldsUp,
+		IncomingFiles:       incomingFiles,
+		AdvertisedRoutes:    prefs.AdvertiseRoutes,
+		AdvertisingExitNode: isAdvertisingExitNode(prefs.AdvertiseRoutes),
+		AcceptRoutes:        prefs.RouteAll,
+		ClientVersion:       status.ClientVersion,
+		UpdateAvailable:     status.ClientVersion != nil && !status.ClientVersion.RunningLatest,
 	}
 
 	for _, peer := range status.Peer {
@@ -174,6 +241,13 @@ func GetState(ctx context.Context) (State, error) {
 		}
 	}
 
+	for _, k := range lock.TrustedKeys {
+		state.LockTrustedKeys = append(state.LockTrustedKeys, k.Key)
+	}
+	for _, peer := range lock.FilteredPeers {
+		state.LockFilteredPeers = append(state.LockFilteredPeers, *peer)
+	}
+
 	if status.ExitNodeStatus != nil {
 		state.CurrentExitNode = &status.ExitNodeStatus.ID
 
@@ -0,0 +1,40 @@
+package libts
+
+import (
+	"context"
+	"io"
+
+	"tailscale.com/client/tailscale/apitype"
+	"tailscale.com/ipn/ipnstate"
+)
+
+// Send a file to a peer over Taildrop.
+func SendFile(ctx context.Context, peer *ipnstate.PeerStatus, name string, size int64, r io.Reader) error {
+	return ts.PushFile(ctx, peer.ID, size, name, r)
+}
+
+// List files waiting to be received over Taildrop.
+func WaitingFiles(ctx context.Context) ([]apitype.WaitingFile, error) {
+	return ts.WaitingFiles(ctx)
+}
+
+// Receive a waiting file, writing its contents to w and then deleting it from the daemon's
+// incoming directory.
+func ReceiveFile(ctx context.Context, name string, w io.Writer) error {
+	rc, _, err := ts.GetWaitingFile(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if _, err := io.Copy(w, rc); err != nil {
+		return err
+	}
+
+	return DeleteWaitingFile(ctx, name)
+}
+
+// Delete a waiting file without receiving it.
+func DeleteWaitingFile(ctx context.Context, name string) error {
+	return ts.DeleteWaitingFile(ctx, name)
+}
@@ -0,0 +1,76 @@
+package libts
+
+import (
+	"context"
+	"time"
+
+	"tailscale.com/ipn/ipnstate"
+	"tailscale.com/tailcfg"
+)
+
+// How often to poll the daemon for self-update progress while an update is in flight.
+// Updates restart tailscaled, so this can't be served off the IPN notify bus the way
+// Watch is.
+const updateProgressPollInterval = 500 * time.Millisecond
+
+// Check whether a newer Tailscale client version is available. Prefers the cached
+// result on Status, which the daemon refreshes periodically from the control plane,
+// and only hits the control-plane update endpoint directly if that's not populated yet.
+func CheckUpdate(ctx context.Context) (*tailcfg.ClientVersion, error) {
+	status, err := Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if status.ClientVersion != nil {
+		return status.ClientVersion, nil
+	}
+
+	return ts.CheckUpdate(ctx)
+}
+
+// Start an in-place self-update to the latest available Tailscale client version.
+// Use UpdateProgress to follow along.
+func StartUpdate(ctx context.Context) error {
+	return ts.StartUpdate(ctx)
+}
+
+// Stream progress notifications for a self-update started by StartUpdate, polling the
+// daemon and forwarding each new ipnstate.UpdateProgress entry it appends to the update
+// log. The returned channel is closed when ctx is canceled or when a poll of the daemon
+// fails, so the stream ends if the daemon is unreachable while it restarts.
+func UpdateProgress(ctx context.Context) (<-chan ipnstate.UpdateProgress, error) {
+	progress := make(chan ipnstate.UpdateProgress)
+
+	go func() {
+		defer close(progress)
+
+		sent := 0
+		ticker := time.NewTicker(updateProgressPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			log, err := ts.UpdateProgress(ctx)
+			if err != nil {
+				return
+			}
+
+			for _, cur := range log[sent:] {
+				select {
+				case progress <- cur:
+				case <-ctx.Done():
+					return
+				}
+			}
+			sent = len(log)
+		}
+	}()
+
+	return progress, nil
+}
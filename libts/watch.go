@@ -0,0 +1,126 @@
+package libts
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"tailscale.com/ipn"
+	"tailscale.com/util/backoff"
+)
+
+// Notification mask used for the IPN bus watcher: a full snapshot up front, then
+// rate-limited deltas so bursts of backend activity don't flood the channel.
+const watchMask = ipn.NotifyInitialState | ipn.NotifyInitialPrefs | ipn.NotifyInitialNetMap | ipn.NotifyRateLimit
+
+// Open a stream of State snapshots backed by the daemon's IPN notification bus, instead of
+// polling GetState on a ticker. The first value sent on the returned channel is the current
+// state; later values are produced by applying each incoming ipn.Notify on top of a cached
+// State, so callers don't pay for a full Status/Prefs/LockStatus round trip on every update.
+//
+// If the bus connection drops (e.g. the daemon restarts), Watch reconnects automatically with
+// exponential backoff and keeps emitting from the same cached State. The only way the returned
+// channels close is ctx being canceled, at which point both channels are closed.
+func Watch(ctx context.Context) (<-chan State, <-chan error, error) {
+	cur, err := fetchState(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	states := make(chan State)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(states)
+		defer close(errs)
+
+		select {
+		case states <- cur:
+		case <-ctx.Done():
+			return
+		}
+
+		bo := backoff.NewBackoff("libts-watch", log.Printf, 30*time.Second)
+
+		for ctx.Err() == nil {
+			err := watchOnce(ctx, &cur, states)
+			if err == nil || ctx.Err() != nil {
+				return
+			}
+
+			select {
+			case errs <- err:
+			default:
+			}
+			bo.BackOff(ctx, err)
+		}
+	}()
+
+	return states, errs, nil
+}
+
+// Stream notifications from the daemon, applying each one to cur and sending the result on
+// states, until the stream breaks or ctx is canceled.
+func watchOnce(ctx context.Context, cur *State, states chan<- State) error {
+	watcher, err := ts.WatchIPNBus(ctx, watchMask)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	for {
+		n, err := watcher.Next()
+		if err != nil {
+			return err
+		}
+
+		if err := applyNotify(ctx, cur, n); err != nil {
+			return err
+		}
+
+		select {
+		case states <- *cur:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Apply an ipn.Notify on top of cur in place. Changes that affect the derived peer lists
+// (backend state, prefs, netmap, a login completing) trigger a full rebuild via fetchState;
+// everything else is folded in directly so frequent updates, like engine byte counters, don't
+// pay for one.
+func applyNotify(ctx context.Context, cur *State, n ipn.Notify) error {
+	if n.State != nil || n.Prefs != nil || n.NetMap != nil || n.LoginFinished != nil {
+		next, err := fetchState(ctx)
+		if err != nil {
+			return err
+		}
+		// fetchState can't see backend error messages, so keep the last one across rebuilds.
+		next.ErrMessage = cur.ErrMessage
+		*cur = next
+	}
+
+	if n.BrowseToURL != nil {
+		cur.AuthURL = *n.BrowseToURL
+	}
+
+	if n.ErrMessage != nil {
+		cur.ErrMessage = *n.ErrMessage
+	}
+
+	if n.IncomingFiles != nil {
+		incomingFiles, err := WaitingFiles(ctx)
+		if err != nil {
+			return err
+		}
+		cur.IncomingFiles = incomingFiles
+	}
+
+	if n.Engine != nil {
+		cur.RxBytes = n.Engine.RBytes
+		cur.TxBytes = n.Engine.WBytes
+	}
+
+	return nil
+}